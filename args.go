@@ -2,6 +2,7 @@ package main
 
 import (
 	"doglog/config"
+	"doglog/debug"
 	"fmt"
 	"github.com/akamensky/argparse"
 	"github.com/araddon/dateparse"
@@ -36,6 +37,11 @@ type options struct {
 	json         bool
 	serverConfig *config.IniFile
 	color        bool
+	timezone     *time.Location
+	grokPattern  string
+	grokFilters  []string
+	output       *outputSpec
+	fields       []string
 }
 
 // parseArgs parses the command-line arguments.
@@ -55,13 +61,27 @@ func parseArgs() *options {
 	end := parser.String("", "end", &argparse.Options{Required: false, Help: "Ending time to search from. Allows variable formats, including '6:45am' or '2019-01-04 12:30:00'. Defaults to now if --start is provided but no --end."})
 	json := parser.Flag("j", "json", &argparse.Options{Required: false, Help: "Output messages in json format. Shows the modified log message, not the untouched message from Datadog. Useful in understanding the fields available when creating Format templates or for further processing."})
 	noColor := parser.Flag("", "no-colors", &argparse.Options{Required: false, Help: "Don't use colors in output."})
+	timezone := parser.String("z", "timezone", &argparse.Options{Required: false, Help: "IANA timezone name (e.g., America/New_York, UTC) used to interpret --start/--end and to display output timestamps. Defaults to the machine's local timezone."})
+	grok := parser.String("", "grok", &argparse.Options{Required: false, Help: "Name of a grok pattern (built-in, e.g., NGINX_ACCESS, or declared in the [grok] section of the config) used to extract named fields from each message."})
+	grokFilter := parser.StringList("", "grok-filter", &argparse.Options{Required: false, Help: "Filter messages on a field extracted by --grok, e.g., 'status>=500'. May be repeated; all filters must match."})
+	output := parser.String("o", "output", &argparse.Options{Required: false, Help: "Output format: text (default), json, yaml, csv, tsv, or tmpl=<file-or-template>. Overrides --json if both are given."})
+	fields := parser.StringList("", "fields", &argparse.Options{Required: false, Help: "Comma-separated field selector used by -o csv/tsv to choose and order columns, e.g., --fields host,service,status,message. May also be repeated."})
 
 	if err := parser.Parse(os.Args); err != nil {
 		invalidArgs(parser, err, "")
 	}
 
-	startDate := strToDate(parser, *start, "The --start date can't be parsed", false)
-	endDate := strToDate(parser, *end, "The --end date can't be parsed", true)
+	loc := time.Local
+	if len(*timezone) > 0 {
+		var err error
+		loc, err = time.LoadLocation(*timezone)
+		if err != nil {
+			invalidArgs(parser, err, "The --timezone can't be loaded")
+		}
+	}
+
+	startDate := strToDate(parser, *start, "The --start date can't be parsed", false, loc)
+	endDate := strToDate(parser, *end, "The --end date can't be parsed", true, loc)
 
 	if *limit <= 0 {
 		var newLimit = DefaultLimit
@@ -73,6 +93,14 @@ func parseArgs() *options {
 		tail = &newTail
 	}
 
+	outputSpec, err := parseOutputFlag(*output)
+	if err != nil {
+		invalidArgs(parser, err, "The --output can't be parsed")
+	}
+	if len(*output) == 0 && *json {
+		outputSpec.format = "json"
+	}
+
 	var newQuery string
 	if len(*service) > 0 {
 		newQuery = "service:" + *service
@@ -83,16 +111,21 @@ func parseArgs() *options {
 	}
 
 	opts := options{
-		service:    *service,
-		query:      *query,
-		limit:      *limit,
-		tail:       *tail,
-		configPath: *configPath,
-		timeRange:  timeRangeToSeconds(parser, *timeRange),
-		startDate:  startDate,
-		endDate:    endDate,
-		json:       *json,
-		color:      !*noColor && isTty(),
+		service:     *service,
+		query:       *query,
+		limit:       *limit,
+		tail:        *tail,
+		configPath:  *configPath,
+		timeRange:   timeRangeToSeconds(parser, *timeRange),
+		startDate:   startDate,
+		endDate:     endDate,
+		json:        *json,
+		color:       !*noColor && isTty(),
+		timezone:    loc,
+		grokPattern: *grok,
+		grokFilters: *grokFilter,
+		output:      outputSpec,
+		fields:      splitFields(*fields),
 	}
 
 	// Read the configuration file
@@ -103,26 +136,30 @@ func parseArgs() *options {
 
 	opts.serverConfig = cfg
 
+	debug.Logf("parsed args: query=%q timeRange=%ds startDate=%v endDate=%v", opts.query, opts.timeRange, opts.startDate, opts.endDate)
+
 	return &opts
 }
 
-// Convert a variable human-friendly date into a time.Time.
-func strToDate(parser *argparse.Parser, dateStr string, errorStr string, defaultToNow bool) *time.Time {
+// Convert a variable human-friendly date into a time.Time, interpreting naked dates and times in loc.
+func strToDate(parser *argparse.Parser, dateStr string, errorStr string, defaultToNow bool, loc *time.Location) *time.Time {
 	var dateTime time.Time
 	var err error
 
 	if len(dateStr) > 0 {
+		now := time.Now().In(loc)
+
 		// Check to see if the date is a time only
 		matched, _ := regexp.MatchString("^[0-9]{1,2}:[0-9]{2}(:[0-9]{2})?([ ]*(am|pm|AM|PM)?)?$", dateStr)
 		if matched {
-			dateStr = time.Now().Format("2006-01-02") + " " + dateStr
+			dateStr = now.Format("2006-01-02") + " " + dateStr
 		}
-		dateTime, err = dateparse.ParseLocal(dateStr)
+		dateTime, err = dateparse.ParseIn(dateStr, loc)
 		if err != nil {
 			invalidArgs(parser, err, errorStr)
 		} else {
 			if dateTime.Year() == 0 {
-				dateTime = dateTime.AddDate(time.Now().Year(), 0, 0)
+				dateTime = dateTime.AddDate(now.Year(), 0, 0)
 			}
 		}
 		if err != nil {
@@ -131,15 +168,30 @@ func strToDate(parser *argparse.Parser, dateStr string, errorStr string, default
 		return &dateTime
 	}
 	if defaultToNow {
-		dateTime = time.Now()
+		dateTime = time.Now().In(loc)
 		return &dateTime
 	}
 	return nil
 }
 
 // Converts a simple human-friendly time range into seconds, e.g., 2h for 2 hours, 3d2h30m for 3 days, 2 hours and
-// 30 minutes.
+// 30 minutes. A bare integer (e.g., "7") is treated as a number of days, and anything Go's time.ParseDuration
+// understands (e.g., "1.5h", "1500ms") is accepted before falling back to the d/h/m/s accumulator below.
+// Durations are rounded to the nearest second; a range that rounds to zero (e.g., "250ms") is
+// rejected rather than silently becoming an empty search window.
 func timeRangeToSeconds(parser *argparse.Parser, timeRange string) int {
+	if days, err := strconv.ParseUint(timeRange, 10, 64); err == nil {
+		return int(days) * 86400
+	}
+
+	if dur, err := time.ParseDuration(timeRange); err == nil {
+		rounded := dur.Round(time.Second)
+		if dur > 0 && rounded == 0 {
+			invalidArgs(parser, fmt.Errorf("%s rounds to a zero-length time range", timeRange), "Time range can't be parsed")
+		}
+		return int(rounded.Seconds())
+	}
+
 	re := regexp.MustCompile("([0-9]*)([a-zA-Z]*)")
 	parts := re.FindAllString(timeRange, -1)
 	var accumulator int
@@ -168,6 +220,26 @@ func timeRangeToSeconds(parser *argparse.Parser, timeRange string) int {
 	return accumulator
 }
 
+// splitFields flattens a --fields value that may be repeated, comma-separated, or both
+// (e.g. --fields host,service --fields status) into a single ordered list of field names.
+func splitFields(raw []string) []string {
+	var fields []string
+	for _, entry := range raw {
+		for _, field := range strings.Split(entry, ",") {
+			if field = strings.TrimSpace(field); len(field) > 0 {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// Expand replaces literal "\n" escape sequences in s with real newlines. Config values and
+// --grok-filter expressions are not passed through it.
+func Expand(s string) string {
+	return strings.ReplaceAll(s, `\n`, "\n")
+}
+
 // Display the help message when a command-line argument is invalid.
 func invalidArgs(parser *argparse.Parser, err error, msg string) {
 	if len(msg) > 0 {
@@ -0,0 +1,97 @@
+// Package config reads doglog's ini-style configuration file (~/.doglog by default). The
+// top-level (unnamed) section holds Datadog credentials (api_key, app_key, site); named
+// [section] blocks hold feature-specific configuration, e.g. [grok] for user-defined grok
+// patterns or [metrics] for doglog's own usage reporting.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// IniFile is a parsed configuration file.
+type IniFile struct {
+	root     map[string]string
+	sections map[string]map[string]string
+}
+
+// New reads and parses the ini file at path. A missing file is not an error: doglog still
+// runs, just without Datadog credentials or any optional feature configuration.
+func New(path string) (*IniFile, error) {
+	cfg := &IniFile{root: map[string]string{}, sections: map[string]map[string]string{}}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	current := cfg.root
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := cfg.sections[name]; !ok {
+				cfg.sections[name] = map[string]string{}
+			}
+			current = cfg.sections[name]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		current[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("config: reading %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// APIKey is the Datadog API key from the top-level section.
+func (c *IniFile) APIKey() string {
+	return c.root["api_key"]
+}
+
+// AppKey is the Datadog application key from the top-level section.
+func (c *IniFile) AppKey() string {
+	return c.root["app_key"]
+}
+
+// Site is the Datadog site to query, e.g. "datadoghq.com" or "datadoghq.eu". Defaults to
+// "datadoghq.com" when unset.
+func (c *IniFile) Site() string {
+	if site := c.root["site"]; len(site) > 0 {
+		return site
+	}
+	return "datadoghq.com"
+}
+
+// Section returns the key/value pairs declared under [name], or nil if there is no such
+// section.
+func (c *IniFile) Section(name string) map[string]string {
+	return c.sections[name]
+}
+
+// MetricsAPIKey is the Datadog API key from the [metrics] section. Falls back to the
+// top-level api_key when [metrics] doesn't set its own, so a single Datadog account can be
+// configured once and used for both log search and usage reporting.
+func (c *IniFile) MetricsAPIKey() string {
+	if key := c.sections["metrics"]["api_key"]; len(key) > 0 {
+		return key
+	}
+	return c.APIKey()
+}
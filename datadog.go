@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"doglog/config"
+	"doglog/debug"
+	"doglog/metrics"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxSearchAttempts is how many attempts doglog makes at a single search page, retrying
+// after a transient (5xx or network) failure, before giving up.
+const maxSearchAttempts = 3
+
+// searchRetryBackoff is the base delay between retry attempts, multiplied by the attempt
+// number (1, 2, ...).
+const searchRetryBackoff = 500 * time.Millisecond
+
+// logEntry is one message returned by the Datadog Logs Search API, trimmed to the fields
+// doglog cares about.
+type logEntry struct {
+	Timestamp  time.Time
+	Message    string
+	Host       string
+	Service    string
+	Tags       []string
+	Attributes map[string]interface{}
+}
+
+// datadogClient searches Datadog's Logs Search API (https://docs.datadoghq.com/api/latest/logs/#search-logs).
+type datadogClient struct {
+	apiKey, appKey, site string
+	httpClient           *http.Client
+	metrics              metrics.Sink
+}
+
+// newDatadogClient builds a client from the api_key/app_key/site in the top-level section
+// of the ini config. sink receives usage metrics for every search; pass metrics.Noop to
+// disable reporting.
+func newDatadogClient(cfg *config.IniFile, sink metrics.Sink) *datadogClient {
+	return &datadogClient{
+		apiKey:     cfg.APIKey(),
+		appKey:     cfg.AppKey(),
+		site:       cfg.Site(),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		metrics:    sink,
+	}
+}
+
+type searchRequestBody struct {
+	Filter struct {
+		Query string `json:"query"`
+		From  string `json:"from"`
+		To    string `json:"to"`
+	} `json:"filter"`
+	Page struct {
+		Limit  int    `json:"limit"`
+		Cursor string `json:"cursor,omitempty"`
+	} `json:"page"`
+	Sort string `json:"sort"`
+}
+
+type searchResponseBody struct {
+	Data []struct {
+		Attributes struct {
+			Timestamp  time.Time              `json:"timestamp"`
+			Message    string                 `json:"message"`
+			Host       string                 `json:"host"`
+			Service    string                 `json:"service"`
+			Tags       []string               `json:"tags"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		Page struct {
+			After string `json:"after"`
+		} `json:"page"`
+	} `json:"meta"`
+}
+
+// search fetches up to limit messages matching query in [from, to).
+func (c *datadogClient) search(query string, from, to time.Time, limit int) ([]logEntry, error) {
+	start := time.Now()
+	entries, bodyLen, err := c.doSearch(query, from, to, limit)
+	c.metrics.Histogram("doglog.search_latency_seconds", time.Since(start).Seconds())
+	if err != nil {
+		c.metrics.Count("doglog.search_errors", 1)
+		return nil, err
+	}
+	c.metrics.Count("doglog.messages_fetched", int64(len(entries)))
+	c.metrics.Count("doglog.bytes_fetched", int64(bodyLen))
+	return entries, nil
+}
+
+// doSearch performs the actual HTTP round trip(s) for search, following pagination cursors
+// until limit messages have been collected or Datadog stops returning one, and returns the
+// total raw response size across every page so search can report it without re-reading the
+// body.
+func (c *datadogClient) doSearch(query string, from, to time.Time, limit int) ([]logEntry, int, error) {
+	var entries []logEntry
+	totalBytes := 0
+	cursor := ""
+
+	for len(entries) < limit {
+		page, bodyLen, nextCursor, err := c.searchPage(query, from, to, limit-len(entries), cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, page...)
+		totalBytes += bodyLen
+
+		if len(nextCursor) == 0 || len(page) == 0 {
+			break
+		}
+		debug.Logf("datadog: following pagination cursor %q (%d/%d messages so far)", nextCursor, len(entries), limit)
+		cursor = nextCursor
+	}
+	return entries, totalBytes, nil
+}
+
+// searchPage fetches a single page of up to limit messages starting at cursor (the empty
+// string for the first page), retrying transient failures for at most maxSearchAttempts
+// attempts in total.
+func (c *datadogClient) searchPage(query string, from, to time.Time, limit int, cursor string) ([]logEntry, int, string, error) {
+	var lastErr error
+	for attempt := 1; attempt <= maxSearchAttempts; attempt++ {
+		entries, bodyLen, nextCursor, retryable, err := c.requestPage(query, from, to, limit, cursor)
+		if err == nil {
+			return entries, bodyLen, nextCursor, nil
+		}
+		lastErr = err
+		if !retryable || attempt == maxSearchAttempts {
+			break
+		}
+		backoff := time.Duration(attempt) * searchRetryBackoff
+		debug.Logf("datadog: search attempt %d/%d failed (%v), retrying in %s", attempt, maxSearchAttempts, err, backoff)
+		time.Sleep(backoff)
+	}
+	return nil, 0, "", lastErr
+}
+
+// requestPage sends a single search request and parses its response. retryable reports
+// whether the failure (if any) is worth retrying, i.e. a network error or a 5xx response;
+// 4xx responses (bad query, bad auth) are not retried since retrying won't change them.
+func (c *datadogClient) requestPage(query string, from, to time.Time, limit int, cursor string) (entries []logEntry, bodyLen int, nextCursor string, retryable bool, err error) {
+	reqBody := searchRequestBody{}
+	reqBody.Filter.Query = query
+	reqBody.Filter.From = from.Format(time.RFC3339)
+	reqBody.Filter.To = to.Format(time.RFC3339)
+	reqBody.Page.Limit = limit
+	reqBody.Page.Cursor = cursor
+	reqBody.Sort = "timestamp"
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, 0, "", false, fmt.Errorf("datadog: encoding request: %w", err)
+	}
+
+	url := fmt.Sprintf("https://api.%s/api/v2/logs/events/search", c.site)
+	debug.Logf("datadog: POST %s query=%q from=%s to=%s limit=%d cursor=%q", url, query, reqBody.Filter.From, reqBody.Filter.To, limit, cursor)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, "", false, fmt.Errorf("datadog: building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", c.apiKey)
+	req.Header.Set("DD-APPLICATION-KEY", c.appKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, "", true, fmt.Errorf("datadog: posting search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, "", true, fmt.Errorf("datadog: reading response: %w", err)
+	}
+	debug.Logf("datadog: response status=%s bytes=%d", resp.Status, len(respBody))
+	if resp.StatusCode >= 300 {
+		return nil, 0, "", resp.StatusCode >= 500, fmt.Errorf("datadog: unexpected status %s", resp.Status)
+	}
+
+	var parsed searchResponseBody
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, 0, "", false, fmt.Errorf("datadog: decoding response: %w", err)
+	}
+
+	entries = make([]logEntry, 0, len(parsed.Data))
+	for _, item := range parsed.Data {
+		entries = append(entries, logEntry{
+			Timestamp:  item.Attributes.Timestamp,
+			Message:    item.Attributes.Message,
+			Host:       item.Attributes.Host,
+			Service:    item.Attributes.Service,
+			Tags:       item.Attributes.Tags,
+			Attributes: item.Attributes.Attributes,
+		})
+	}
+	return entries, len(respBody), parsed.Meta.Page.After, false, nil
+}
+
+// messageToFields converts a logEntry into the field map that grok extraction, filtering,
+// and the output renderers all share. The timestamp field is formatted in loc, the same
+// --timezone used to interpret --start/--end, so what's displayed matches what was asked for.
+func messageToFields(entry logEntry, loc *time.Location) map[string]interface{} {
+	fields := map[string]interface{}{
+		"timestamp": entry.Timestamp.In(loc).Format(time.RFC3339),
+		"message":   entry.Message,
+		"host":      entry.Host,
+		"service":   entry.Service,
+		"tags":      entry.Tags,
+	}
+	for k, v := range entry.Attributes {
+		fields[k] = v
+	}
+	return fields
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os/user"
 	"testing"
+	"time"
+
+	"github.com/akamensky/argparse"
 )
 
 func ExampleExpand() {
@@ -23,3 +26,71 @@ func TestExpandPath(t *testing.T) {
 		t.Errorf("expandPath(\"~/.doglog\") = %s", path1)
 	}
 }
+
+func TestTimeRangeToSeconds(t *testing.T) {
+	parser := argparse.NewParser("doglog", "")
+
+	cases := map[string]int{
+		"2h":       2 * 3600,
+		"3d2h30m":  3*86400 + 2*3600 + 30*60,
+		"7":        7 * 86400,
+		"1h30m15s": 1*3600 + 30*60 + 15,
+		"1.5h":     int(90 * 60),
+		"1500ms":   2,
+	}
+
+	for input, expected := range cases {
+		if got := timeRangeToSeconds(parser, input); got != expected {
+			t.Errorf("timeRangeToSeconds(%q) = %d, want %d", input, got, expected)
+		}
+	}
+}
+
+func TestStrToDateUsesGivenTimezone(t *testing.T) {
+	parser := argparse.NewParser("doglog", "")
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("time.LoadLocation(\"America/New_York\") error = %v", err)
+	}
+
+	inNewYork := strToDate(parser, "2020-01-04 12:30:00", "", false, newYork)
+	inUTC := strToDate(parser, "2020-01-04 12:30:00", "", false, time.UTC)
+
+	if inNewYork == nil || inUTC == nil {
+		t.Fatalf("strToDate() = %v, %v, want non-nil", inNewYork, inUTC)
+	}
+
+	if inNewYork.Location() != newYork {
+		t.Errorf("inNewYork.Location() = %v, want %v", inNewYork.Location(), newYork)
+	}
+
+	// The same wall-clock time parsed in two different zones represents a different instant;
+	// America/New_York is 5 hours behind UTC in January (EST, no DST), so 12:30 in New York
+	// is a later instant than 12:30 UTC.
+	if diff := inNewYork.Sub(*inUTC); diff != 5*time.Hour {
+		t.Errorf("inNewYork.Sub(inUTC) = %v, want 5h", diff)
+	}
+}
+
+func TestStrToDateDefaultsTimeOnlyStringToTodayInZone(t *testing.T) {
+	parser := argparse.NewParser("doglog", "")
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("time.LoadLocation(\"America/New_York\") error = %v", err)
+	}
+
+	got := strToDate(parser, "1:32pm", "", false, newYork)
+	if got == nil {
+		t.Fatalf("strToDate() = nil, want non-nil")
+	}
+
+	want := time.Now().In(newYork).Format("2006-01-02")
+	if got.Format("2006-01-02") != want {
+		t.Errorf("strToDate(\"1:32pm\").Format(...) = %s, want %s", got.Format("2006-01-02"), want)
+	}
+	if got.Hour() != 13 || got.Minute() != 32 {
+		t.Errorf("strToDate(\"1:32pm\") = %v, want 13:32", got)
+	}
+}
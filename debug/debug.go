@@ -0,0 +1,81 @@
+// Package debug provides a timestamped, goroutine-tagged logger that is entirely opt-in:
+// it is a no-op unless the DEBUG_LOG environment variable is set, so the production binary
+// pays no cost for it. Set DEBUG_LOG to a file path to log there, or to "-" to log to stderr.
+//
+// This is meant for answering "what did doglog actually send to Datadog, and what came
+// back?" when a user reports a query that silently returned nothing.
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	once    sync.Once
+	mu      sync.Mutex
+	out     io.Writer
+	enabled bool
+)
+
+// setup resolves DEBUG_LOG the first time debug logging is used, rather than at package
+// init, so tests can exercise it by setting the environment variable before the first call.
+func setup() {
+	once.Do(func() {
+		path := os.Getenv("DEBUG_LOG")
+		if len(path) == 0 {
+			return
+		}
+
+		if path == "-" {
+			out = os.Stderr
+			enabled = true
+			return
+		}
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "debug: can't open DEBUG_LOG %q: %v\n", path, err)
+			return
+		}
+		out = f
+		enabled = true
+	})
+}
+
+// Enabled reports whether debug logging is turned on, so callers can skip building an
+// expensive log line (e.g., dumping a full HTTP response body) when it would be discarded.
+func Enabled() bool {
+	setup()
+	return enabled
+}
+
+// Logf writes a timestamped, goroutine-tagged debug line. It is a no-op unless DEBUG_LOG
+// is set.
+func Logf(format string, args ...interface{}) {
+	setup()
+	if !enabled {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	fmt.Fprintf(out, "%s [goroutine %d] %s\n", time.Now().Format(time.RFC3339Nano), goroutineID(), fmt.Sprintf(format, args...))
+}
+
+// goroutineID extracts the calling goroutine's ID from its stack trace header
+// ("goroutine 123 [running]: ..."). It's a debug-only convenience, not meant for
+// anything load-bearing.
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	var id int64
+	fmt.Sscanf(string(bytes.Fields(buf[:n])[1]), "%d", &id)
+	return id
+}
@@ -0,0 +1,27 @@
+package debug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogfWritesWhenEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	os.Setenv("DEBUG_LOG", path)
+	defer os.Unsetenv("DEBUG_LOG")
+
+	if !Enabled() {
+		t.Fatalf("Enabled() = false, want true when DEBUG_LOG is set")
+	}
+
+	Logf("hello %s", "world")
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(contents) == 0 {
+		t.Errorf("expected debug.log to contain a log line, got empty file")
+	}
+}
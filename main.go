@@ -0,0 +1,174 @@
+package main
+
+import (
+	"doglog/config"
+	"doglog/metrics"
+	"doglog/parser/grok"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// tailPollInterval is how often doglog re-queries Datadog while --tail is active.
+const tailPollInterval = 5 * time.Second
+
+// metricsFlushInterval is how often doglog flushes its own usage metrics while --tail is
+// active. A normal (non-tail) run flushes once, after its single fetch.
+const metricsFlushInterval = 30 * time.Second
+
+func main() {
+	opts := parseArgs()
+
+	sink := metrics.New(metricsConfigFrom(opts.serverConfig))
+	defer sink.Flush()
+
+	client := newDatadogClient(opts.serverConfig, sink)
+
+	from := time.Now().Add(-time.Duration(opts.timeRange) * time.Second)
+	if opts.startDate != nil {
+		from = *opts.startDate
+	}
+	to := time.Now()
+	if opts.endDate != nil {
+		to = *opts.endDate
+	}
+
+	last, err := fetchAndRender(client, opts, from, to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if !opts.tail {
+		return
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	metrics.StartPeriodicFlush(sink, metricsFlushInterval, done)
+
+	for {
+		time.Sleep(tailPollInterval)
+
+		next, err := fetchAndRender(client, opts, last.Add(time.Millisecond), time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		last = next
+	}
+}
+
+// metricsConfigFrom builds a metrics.Config from the [metrics] section of the ini config,
+// e.g.:
+//
+//	[metrics]
+//	host = build-host-01
+//	site = datadoghq.com
+//	tags = team:on-call,env:prod
+//
+// Returns nil if there's no [metrics] section or no usable API key, so metrics.New falls
+// back to a no-op sink.
+func metricsConfigFrom(cfg *config.IniFile) *metrics.Config {
+	section := cfg.Section("metrics")
+	if section == nil {
+		return nil
+	}
+	apiKey := cfg.MetricsAPIKey()
+	if len(apiKey) == 0 {
+		return nil
+	}
+
+	var tags []string
+	if raw := section["tags"]; len(raw) > 0 {
+		tags = strings.Split(raw, ",")
+	}
+
+	return &metrics.Config{
+		APIKey: apiKey,
+		Host:   section["host"],
+		Site:   section["site"],
+		Tags:   tags,
+	}
+}
+
+// fetchAndRender runs one search over [from, to) and renders the result via renderOutput,
+// returning the timestamp of the most recent message seen (or from, unchanged, if nothing
+// matched) so --tail can advance its window.
+func fetchAndRender(client *datadogClient, opts *options, from, to time.Time) (time.Time, error) {
+	entries, err := client.search(opts.query, from, to, opts.limit)
+	if err != nil {
+		return from, err
+	}
+
+	messages := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		messages[i] = messageToFields(entry, opts.timezone)
+	}
+
+	messages, err = applyGrok(opts, messages)
+	if err != nil {
+		return from, err
+	}
+
+	if err := renderOutput(os.Stdout, opts.output, messages, opts.fields); err != nil {
+		return from, err
+	}
+
+	last := from
+	for _, entry := range entries {
+		if entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+	}
+	return last, nil
+}
+
+// applyGrok extracts fields named by --grok into each message and drops messages that
+// don't satisfy every --grok-filter. It's a no-op when --grok wasn't passed.
+func applyGrok(opts *options, messages []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(opts.grokPattern) == 0 {
+		return messages, nil
+	}
+
+	registry := grok.WithFallback(grok.Patterns(opts.serverConfig.Section("grok")))
+	rawPattern, ok := registry.Lookup(opts.grokPattern)
+	if !ok {
+		return nil, fmt.Errorf("unknown grok pattern %q", opts.grokPattern)
+	}
+	pattern, err := grok.Compile(opts.grokPattern, rawPattern, registry)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]*grok.Filter, 0, len(opts.grokFilters))
+	for _, expr := range opts.grokFilters {
+		filter, err := grok.ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	filtered := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		if fields, ok := pattern.Extract(fmt.Sprintf("%v", message["message"])); ok {
+			for name, value := range fields {
+				message[name] = value
+			}
+		}
+
+		matchesAll := true
+		for _, filter := range filters {
+			if !filter.Matches(message) {
+				matchesAll = false
+				break
+			}
+		}
+		if matchesAll {
+			filtered = append(filtered, message)
+		}
+	}
+	return filtered, nil
+}
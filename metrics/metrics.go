@@ -0,0 +1,175 @@
+// Package metrics lets doglog report its own operational metrics (messages fetched, bytes
+// processed, API errors, query latency) back to Datadog, so a team running doglog widely
+// can see who's issuing expensive queries and spot Datadog-side rate limiting. Reporting is
+// opt-in: Sink is a pluggable interface, and New returns a no-op implementation whenever the
+// [metrics] section of the ini config is absent or incomplete.
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Sink is the interface doglog reports metrics through. The zero value of Config produces
+// a Sink whose methods all do nothing, so call sites never need a nil check.
+type Sink interface {
+	// Count adds value to a monotonic counter, e.g. messages fetched or API errors.
+	Count(name string, value int64, tags ...string)
+	// Gauge records a point-in-time value, e.g. time-to-first-message.
+	Gauge(name string, value float64, tags ...string)
+	// Histogram records a distribution sample, e.g. query latency.
+	Histogram(name string, value float64, tags ...string)
+	// Flush sends any buffered metrics to their destination. Callers should call it once
+	// at process exit, and periodically during --tail.
+	Flush() error
+}
+
+// Config configures the Datadog series sink, read from the [metrics] section of the ini
+// config.
+type Config struct {
+	APIKey string
+	// Host is the host tag attached to every series point, e.g. the machine running doglog.
+	Host string
+	// Site is the Datadog site to report to, e.g. "datadoghq.com" or "datadoghq.eu".
+	// Defaults to "datadoghq.com".
+	Site string
+	// Tags are extra tags (e.g. "team:on-call") attached to every series point.
+	Tags []string
+}
+
+// New returns a Datadog-backed Sink if cfg has an API key configured, or Noop otherwise.
+func New(cfg *Config) Sink {
+	if cfg == nil || len(cfg.APIKey) == 0 {
+		return Noop
+	}
+	return newDatadogSink(*cfg)
+}
+
+// Noop is a Sink whose methods all do nothing, used when metrics reporting isn't configured.
+var Noop Sink = noopSink{}
+
+type noopSink struct{}
+
+func (noopSink) Count(string, int64, ...string)       {}
+func (noopSink) Gauge(string, float64, ...string)     {}
+func (noopSink) Histogram(string, float64, ...string) {}
+func (noopSink) Flush() error                         { return nil }
+
+// point is one [timestamp, value] pair in the Datadog series payload.
+type point [2]float64
+
+// series is a single metric series in the Datadog series payload, see
+// https://docs.datadoghq.com/api/latest/metrics/#submit-metrics.
+type series struct {
+	Metric string   `json:"metric"`
+	Points []point  `json:"points"`
+	Type   string   `json:"type"`
+	Host   string   `json:"host,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+type seriesPayload struct {
+	Series []series `json:"series"`
+}
+
+// datadogSink buffers series points in memory and POSTs them to the Datadog series API
+// on Flush.
+type datadogSink struct {
+	cfg    Config
+	client *http.Client
+
+	mu     sync.Mutex
+	buffer []series
+}
+
+func newDatadogSink(cfg Config) *datadogSink {
+	if len(cfg.Site) == 0 {
+		cfg.Site = "datadoghq.com"
+	}
+	return &datadogSink{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (d *datadogSink) record(metricType, name string, value float64, tags []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.buffer = append(d.buffer, series{
+		Metric: name,
+		Points: []point{{float64(time.Now().Unix()), value}},
+		Type:   metricType,
+		Host:   d.cfg.Host,
+		Tags:   append(append([]string{}, d.cfg.Tags...), tags...),
+	})
+}
+
+func (d *datadogSink) Count(name string, value int64, tags ...string) {
+	d.record("count", name, float64(value), tags)
+}
+
+func (d *datadogSink) Gauge(name string, value float64, tags ...string) {
+	d.record("gauge", name, value, tags)
+}
+
+func (d *datadogSink) Histogram(name string, value float64, tags ...string) {
+	d.record("histogram", name, value, tags)
+}
+
+// Flush POSTs any buffered series points to Datadog and clears the buffer, regardless of
+// whether the request succeeds, so a single failed flush doesn't grow the buffer unbounded.
+func (d *datadogSink) Flush() error {
+	d.mu.Lock()
+	pending := d.buffer
+	d.buffer = nil
+	d.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(seriesPayload{Series: pending})
+	if err != nil {
+		return fmt.Errorf("metrics: marshaling series payload: %w", err)
+	}
+
+	url := fmt.Sprintf("https://api.%s/api/v1/series", d.cfg.Site)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("metrics: building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", d.cfg.APIKey)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("metrics: posting series: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("metrics: posting series: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// StartPeriodicFlush flushes sink every interval until done is closed, ignoring (rather than
+// failing on) flush errors since metrics reporting should never interrupt doglog's primary
+// job of showing logs. Intended for use during --tail, where the process may run for a long
+// time between the normal end-of-run flush.
+func StartPeriodicFlush(sink Sink, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = sink.Flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+}
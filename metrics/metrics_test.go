@@ -0,0 +1,32 @@
+package metrics
+
+import "testing"
+
+func TestNewWithoutAPIKeyReturnsNoop(t *testing.T) {
+	if sink := New(nil); sink != Noop {
+		t.Errorf("New(nil) = %v, want Noop", sink)
+	}
+	if sink := New(&Config{}); sink != Noop {
+		t.Errorf("New(&Config{}) = %v, want Noop", sink)
+	}
+}
+
+func TestNewWithAPIKeyReturnsDatadogSink(t *testing.T) {
+	sink := New(&Config{APIKey: "abc123", Host: "build-host"})
+	if _, ok := sink.(*datadogSink); !ok {
+		t.Errorf("New() with an API key = %T, want *datadogSink", sink)
+	}
+}
+
+func TestRecordBuffersPoints(t *testing.T) {
+	sink := newDatadogSink(Config{APIKey: "abc123"})
+	sink.Count("doglog.messages_fetched", 5, "service:send-email")
+	sink.Gauge("doglog.time_to_first_message", 0.42)
+
+	if len(sink.buffer) != 2 {
+		t.Fatalf("len(buffer) = %d, want 2", len(sink.buffer))
+	}
+	if sink.buffer[0].Type != "count" || sink.buffer[1].Type != "gauge" {
+		t.Errorf("buffered series types = %q, %q", sink.buffer[0].Type, sink.buffer[1].Type)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// outputSpec is the parsed form of the -o/--output flag: a format name, plus the extra
+// configuration that the tmpl format needs (the template source, loaded from a file if
+// the text after "tmpl=" names one that exists, otherwise treated as an inline template string).
+type outputSpec struct {
+	format   string
+	template string
+}
+
+// supportedOutputFormats lists the values -o/--output accepts, including the default "text".
+var supportedOutputFormats = map[string]bool{
+	"text": true,
+	"json": true,
+	"yaml": true,
+	"csv":  true,
+	"tsv":  true,
+	"tmpl": true,
+}
+
+// parseOutputFlag parses the -o/--output flag value, e.g. "json", "csv", or
+// "tmpl=/path/to/file.tmpl" / "tmpl={{.host}}: {{.message}}".
+func parseOutputFlag(raw string) (*outputSpec, error) {
+	if len(raw) == 0 {
+		return &outputSpec{format: "text"}, nil
+	}
+
+	format, rest := raw, ""
+	if idx := strings.Index(raw, "="); idx >= 0 {
+		format, rest = raw[:idx], raw[idx+1:]
+	}
+
+	if !supportedOutputFormats[format] {
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+	if format == "tmpl" && len(rest) == 0 {
+		return nil, fmt.Errorf("tmpl output format requires a template, e.g. -o tmpl=/path/to/file.tmpl")
+	}
+
+	spec := &outputSpec{format: format, template: rest}
+	if format == "tmpl" {
+		if contents, err := os.ReadFile(rest); err == nil {
+			spec.template = string(contents)
+		}
+	}
+	return spec, nil
+}
+
+// writeText writes one line per message: the "message" field if the message map has one,
+// or the whole field map otherwise. This is the default format, used when -o/--output and
+// --json are both omitted.
+func writeText(w io.Writer, messages []map[string]interface{}) error {
+	for _, message := range messages {
+		line := message["message"]
+		if line == nil {
+			line = message
+		}
+		if _, err := fmt.Fprintf(w, "%v\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes one JSON object per message, one per line.
+func writeJSON(w io.Writer, messages []map[string]interface{}) error {
+	enc := json.NewEncoder(w)
+	for _, message := range messages {
+		if err := enc.Encode(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML writes one YAML document per message to w.
+func writeYAML(w io.Writer, messages []map[string]interface{}) error {
+	for _, message := range messages {
+		out, err := yaml.Marshal(message)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDelimited writes messages as CSV (comma) or TSV (tab), selecting and ordering
+// columns per fields and writing a header row first.
+func writeDelimited(w io.Writer, messages []map[string]interface{}, fields []string, delimiter rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	for _, message := range messages {
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = fmt.Sprintf("%v", message[field])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// templateFuncs are the helpers available to -o tmpl= templates, in addition to the
+// message field map itself.
+var templateFuncs = template.FuncMap{
+	"now": time.Now,
+	"color": func(code, s string) string {
+		return "\x1b[" + code + "m" + s + "\x1b[0m"
+	},
+	"trunc": func(n int, s string) string {
+		if len(s) <= n {
+			return s
+		}
+		return s[:n]
+	},
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+}
+
+// writeTemplate renders each message through a Go text/template, one execution per message.
+func writeTemplate(w io.Writer, messages []map[string]interface{}, tmplSource string) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(tmplSource)
+	if err != nil {
+		return fmt.Errorf("parsing -o tmpl: %w", err)
+	}
+	for _, message := range messages {
+		if err := tmpl.Execute(w, message); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderOutput dispatches to the writer for spec.format. fields is only used by the
+// csv/tsv formats; an empty fields selector is an error for those formats since there is
+// no sensible default column order.
+func renderOutput(w io.Writer, spec *outputSpec, messages []map[string]interface{}, fields []string) error {
+	switch spec.format {
+	case "text":
+		return writeText(w, messages)
+	case "json":
+		return writeJSON(w, messages)
+	case "yaml":
+		return writeYAML(w, messages)
+	case "csv":
+		if len(fields) == 0 {
+			return fmt.Errorf("-o csv requires --fields")
+		}
+		return writeDelimited(w, messages, fields, ',')
+	case "tsv":
+		if len(fields) == 0 {
+			return fmt.Errorf("-o tsv requires --fields")
+		}
+		return writeDelimited(w, messages, fields, '\t')
+	case "tmpl":
+		return writeTemplate(w, messages, spec.template)
+	default:
+		return fmt.Errorf("renderOutput: unhandled format %q", spec.format)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderOutputAllFormats(t *testing.T) {
+	messages := []map[string]interface{}{
+		{"host": "web-1", "service": "send-email", "status": 200, "message": "request handled"},
+	}
+
+	cases := []struct {
+		name    string
+		spec    *outputSpec
+		fields  []string
+		wantSub string
+	}{
+		{"default text", &outputSpec{format: "text"}, nil, "request handled"},
+		{"json", &outputSpec{format: "json"}, nil, `"host":"web-1"`},
+		{"yaml", &outputSpec{format: "yaml"}, nil, "host: web-1"},
+		{"csv", &outputSpec{format: "csv"}, []string{"host", "status"}, "web-1,200"},
+		{"tsv", &outputSpec{format: "tsv"}, []string{"host", "status"}, "web-1\t200"},
+		{"tmpl", &outputSpec{format: "tmpl", template: "{{.host}}: {{.message}}"}, nil, "web-1: request handled"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := renderOutput(&buf, c.spec, messages, c.fields); err != nil {
+			t.Errorf("%s: renderOutput() error = %v", c.name, err)
+			continue
+		}
+		if !strings.Contains(buf.String(), c.wantSub) {
+			t.Errorf("%s: renderOutput() = %q, want substring %q", c.name, buf.String(), c.wantSub)
+		}
+	}
+}
+
+func TestRenderOutputCSVRequiresFields(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderOutput(&buf, &outputSpec{format: "csv"}, nil, nil)
+	if err == nil {
+		t.Errorf("renderOutput() with -o csv and no --fields should have errored")
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	got := splitFields([]string{"host,service", " status "})
+	want := []string{"host", "service", "status"}
+	if len(got) != len(want) {
+		t.Fatalf("splitFields() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("splitFields()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package grok
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Filter is a single --grok-filter expression such as "status>=500" or "method=GET",
+// evaluated against the typed fields a Pattern extracted from a message.
+type Filter struct {
+	field string
+	op    string
+	value string
+}
+
+var filterRe = regexp.MustCompile(`^\s*([a-zA-Z0-9_]+)\s*(>=|<=|!=|==|=|>|<)\s*(.*?)\s*$`)
+
+// ParseFilter parses a single "field<op>value" expression. Supported operators are
+// ==, =, !=, <, <=, >, >=, where = is accepted as a synonym for ==.
+func ParseFilter(expr string) (*Filter, error) {
+	m := filterRe.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("grok: invalid filter expression %q", expr)
+	}
+	op := m[2]
+	if op == "=" {
+		op = "=="
+	}
+	return &Filter{field: m[1], op: op, value: m[3]}, nil
+}
+
+// Matches reports whether fields (as produced by Pattern.Extract) satisfies the filter.
+// Numeric comparisons are used when the field holds an int or float64 (e.g. from a :int or
+// :float capture) and the filter value parses as a number; a numeric string such as an
+// untyped capture is not converted. Otherwise the comparison falls back to string
+// equality/inequality, and any ordering operator against a non-numeric field is treated as
+// non-matching rather than an error.
+func (f *Filter) Matches(fields map[string]interface{}) bool {
+	actual, ok := fields[f.field]
+	if !ok {
+		return false
+	}
+
+	if actualNum, actualIsNum := toFloat(actual); actualIsNum {
+		if wantNum, err := strconv.ParseFloat(f.value, 64); err == nil {
+			return compareNum(actualNum, f.op, wantNum)
+		}
+	}
+
+	actualStr := fmt.Sprintf("%v", actual)
+	switch f.op {
+	case "==":
+		return actualStr == f.value
+	case "!=":
+		return actualStr != f.value
+	default:
+		return false
+	}
+}
+
+func compareNum(actual float64, op string, want float64) bool {
+	switch op {
+	case "==":
+		return actual == want
+	case "!=":
+		return actual != want
+	case "<":
+		return actual < want
+	case "<=":
+		return actual <= want
+	case ">":
+		return actual > want
+	case ">=":
+		return actual >= want
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
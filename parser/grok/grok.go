@@ -0,0 +1,143 @@
+// Package grok compiles logstash-style grok patterns into regular expressions and extracts named,
+// typed fields from log message text so they can be merged into the field map that Format templates
+// render from.
+package grok
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// fieldSpec describes one %{PATTERN:name[:type]} capture found in a grok pattern.
+type fieldSpec struct {
+	name     string
+	typeName string
+}
+
+// Pattern is a compiled grok pattern ready to extract fields from log lines.
+type Pattern struct {
+	name   string
+	re     *regexp.Regexp
+	fields map[string]fieldSpec
+}
+
+// Name returns the name the pattern was registered or compiled under.
+func (p *Pattern) Name() string {
+	return p.name
+}
+
+// Extract applies the pattern to message and returns the captured fields, converted to the
+// declared type (string, int, or float64). ok is false if the pattern did not match at all.
+func (p *Pattern) Extract(message string) (fields map[string]interface{}, ok bool) {
+	match := p.re.FindStringSubmatch(message)
+	if match == nil {
+		return nil, false
+	}
+
+	fields = make(map[string]interface{}, len(p.fields))
+	for i, name := range p.re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		spec, known := p.fields[name]
+		if !known {
+			continue
+		}
+		fields[spec.name] = convert(spec.typeName, match[i])
+	}
+	return fields, true
+}
+
+// convert coerces a raw capture into the declared type, falling back to the raw string on
+// a parse failure so a malformed field never aborts extraction of the rest of the message.
+func convert(typeName string, raw string) interface{} {
+	switch typeName {
+	case "int":
+		if n, err := strconv.Atoi(raw); err == nil {
+			return n
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	}
+	return raw
+}
+
+// namedGroupRe matches a single %{PATTERN:name} or %{PATTERN:name:type} reference.
+var namedGroupRe = regexp.MustCompile(`%\{([A-Z0-9_]+)(?::([a-zA-Z0-9_]+))?(?::(int|float))?\}`)
+
+// Compile expands pattern (which may reference other named patterns, built-in or user supplied,
+// via %{NAME} or %{NAME:field} or %{NAME:field:type}) against registry and returns a ready-to-use
+// Pattern. Unknown pattern names are reported as an error rather than silently matching nothing.
+func Compile(name string, pattern string, registry Registry) (*Pattern, error) {
+	fields := make(map[string]fieldSpec)
+	groupIndex := 0
+
+	expanded, err := expand(pattern, registry, fields, &groupIndex, make(map[string]bool))
+	if err != nil {
+		return nil, fmt.Errorf("grok: compiling %q: %w", name, err)
+	}
+
+	// Deliberately unanchored: real log messages almost always carry surrounding context
+	// (a syslog prefix, trailing metadata) around the part a grok pattern describes, so
+	// requiring a whole-line match would fail to extract from the common case.
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("grok: compiling %q: %w", name, err)
+	}
+
+	return &Pattern{name: name, re: re, fields: fields}, nil
+}
+
+// expand recursively substitutes %{...} references in pattern with their underlying regex,
+// renaming captures to unique group names so nested patterns can't collide with each other.
+func expand(pattern string, registry Registry, fields map[string]fieldSpec, groupIndex *int, seen map[string]bool) (string, error) {
+	var out []byte
+	last := 0
+
+	for _, loc := range namedGroupRe.FindAllStringSubmatchIndex(pattern, -1) {
+		out = append(out, pattern[last:loc[0]]...)
+
+		primitive := pattern[loc[2]:loc[3]]
+		var fieldName, typeName string
+		if loc[4] != -1 {
+			fieldName = pattern[loc[4]:loc[5]]
+		}
+		if loc[6] != -1 {
+			typeName = pattern[loc[6]:loc[7]]
+		}
+
+		if seen[primitive] {
+			return "", fmt.Errorf("circular reference to %%{%s}", primitive)
+		}
+
+		underlying, ok := registry.Lookup(primitive)
+		if !ok {
+			return "", fmt.Errorf("unknown pattern %%{%s}", primitive)
+		}
+
+		seen[primitive] = true
+		sub, err := expand(underlying, registry, fields, groupIndex, seen)
+		delete(seen, primitive)
+		if err != nil {
+			return "", err
+		}
+
+		if fieldName == "" {
+			out = append(out, []byte("(?:"+sub+")")...)
+			last = loc[1]
+			continue
+		}
+
+		*groupIndex++
+		groupName := fmt.Sprintf("f%d", *groupIndex)
+		fields[groupName] = fieldSpec{name: fieldName, typeName: typeName}
+		out = append(out, []byte("(?P<"+groupName+">"+sub+")")...)
+		last = loc[1]
+	}
+	out = append(out, pattern[last:]...)
+
+	return string(out), nil
+}
@@ -0,0 +1,59 @@
+package grok
+
+import "testing"
+
+func TestCompileAndExtract(t *testing.T) {
+	pattern, err := Compile("test", `%{IP:client} %{INT:status:int}`, Builtins)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	fields, ok := pattern.Extract("10.0.0.1 200")
+	if !ok {
+		t.Fatalf("Extract() did not match")
+	}
+	if fields["client"] != "10.0.0.1" {
+		t.Errorf("fields[\"client\"] = %v, want 10.0.0.1", fields["client"])
+	}
+	if fields["status"] != 200 {
+		t.Errorf("fields[\"status\"] = %v, want 200", fields["status"])
+	}
+}
+
+func TestExtractWithSurroundingContext(t *testing.T) {
+	pattern, err := Compile("test", `%{IP:client} %{INT:status:int}`, Builtins)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	fields, ok := pattern.Extract("Jul 27 10:00:00 myhost myapp: 10.0.0.1 200 took 12ms")
+	if !ok {
+		t.Fatalf("Extract() did not match a message with leading and trailing text")
+	}
+	if fields["client"] != "10.0.0.1" {
+		t.Errorf("fields[\"client\"] = %v, want 10.0.0.1", fields["client"])
+	}
+	if fields["status"] != 200 {
+		t.Errorf("fields[\"status\"] = %v, want 200", fields["status"])
+	}
+}
+
+func TestCompileUnknownPattern(t *testing.T) {
+	if _, err := Compile("test", `%{NOPE:field}`, Builtins); err == nil {
+		t.Errorf("Compile() with unknown primitive should have errored")
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	filter, err := ParseFilter("status>=500")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+
+	if !filter.Matches(map[string]interface{}{"status": 503}) {
+		t.Errorf("Matches() = false, want true for status 503")
+	}
+	if filter.Matches(map[string]interface{}{"status": 200}) {
+		t.Errorf("Matches() = true, want false for status 200")
+	}
+}
@@ -0,0 +1,74 @@
+package grok
+
+// Registry resolves a grok primitive name (e.g., "IP", "NUMBER") to its underlying regular
+// expression fragment. Callers can layer a file-backed registry over Builtins to add or
+// override patterns without forking the built-in set.
+type Registry interface {
+	// Lookup returns the regex fragment registered under name, and whether it was found.
+	Lookup(name string) (pattern string, ok bool)
+}
+
+// Patterns is a Registry backed by a plain map, used both for the built-in set and for
+// patterns loaded from the [grok] section of the ini config.
+type Patterns map[string]string
+
+// Lookup implements Registry.
+func (p Patterns) Lookup(name string) (string, bool) {
+	pattern, ok := p[name]
+	return pattern, ok
+}
+
+// layered looks a name up in override first, falling back to base. Used so a user's
+// config-supplied patterns can reference or shadow the built-ins.
+type layered struct {
+	override Registry
+	base     Registry
+}
+
+// Lookup implements Registry.
+func (l layered) Lookup(name string) (string, bool) {
+	if pattern, ok := l.override.Lookup(name); ok {
+		return pattern, true
+	}
+	return l.base.Lookup(name)
+}
+
+// WithFallback returns a Registry that consults override first and falls back to Builtins.
+func WithFallback(override Registry) Registry {
+	return layered{override: override, base: Builtins}
+}
+
+// Builtins is the built-in primitive and composite pattern registry, covering the common
+// fields seen in Apache/Nginx access logs, syslog, and loosely-structured JSON-ish lines.
+// It mirrors the subset of logstash's grok-patterns that doglog users ask for most often;
+// richer patterns can be layered on top via the [grok] section of the ini config.
+var Builtins = Patterns{
+	// Primitives
+	"INT":          `[+-]?(?:[0-9]+)`,
+	"NUMBER":       `[+-]?(?:[0-9]+(?:\.[0-9]+)?)`,
+	"WORD":         `\b\w+\b`,
+	"NOTSPACE":     `\S+`,
+	"SPACE":        `\s*`,
+	"DATA":         `.*?`,
+	"GREEDYDATA":   `.*`,
+	"QUOTEDSTRING": `"(?:[^"\\]|\\.)*"`,
+
+	"IPV4": `(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)`,
+	"IPV6": `(?:[0-9A-Fa-f]{1,4}:){1,7}[0-9A-Fa-f]{1,4}|::1`,
+	"IP":   `(?:%{IPV4}|%{IPV6})`,
+
+	"HOSTNAME": `\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)`,
+	"USERNAME": `[a-zA-Z0-9._-]+`,
+
+	"MONTH":    `\b(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|Jun(?:e)?|Jul(?:y)?|Aug(?:ust)?|Sep(?:tember)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\b`,
+	"MONTHDAY": `(?:(?:0[1-9])|(?:[12][0-9])|(?:3[01])|[1-9])`,
+	"TIME":     `(?:2[0123]|[01]?[0-9]):(?:[0-5][0-9])(?::(?:(?:[0-5][0-9]|60)(?:[:.,][0-9]+)?))?`,
+
+	"TIMESTAMP_ISO8601": `[0-9]{4}-[0-9]{2}-[0-9]{2}[T ][0-9]{2}:[0-9]{2}:[0-9]{2}(?:\.[0-9]+)?(?:Z|[+-][0-9]{2}:?[0-9]{2})?`,
+	"SYSLOGTIMESTAMP":   `%{MONTH} +%{MONTHDAY} %{TIME}`,
+
+	// Composites
+	"COMMONAPACHELOG": `%{IP:clientip} %{NOTSPACE:ident} %{NOTSPACE:auth} \[%{DATA:timestamp}\] "(?:%{WORD:verb} %{NOTSPACE:request}(?: HTTP/%{NUMBER:httpversion})?|%{DATA})" %{INT:status:int} (?:-|%{INT:bytes:int})`,
+	"NGINX_ACCESS":    `%{IP:client} - %{USERNAME:user} \[%{DATA:ts}\] "%{WORD:method} %{NOTSPACE:request} HTTP/%{NUMBER:httpversion}" %{INT:status:int} %{INT:bytes:int} "%{DATA:referrer}" "%{DATA:agent}"`,
+	"SYSLOGLINE":      `%{SYSLOGTIMESTAMP:timestamp} %{HOSTNAME:host} %{WORD:program}(?:\[%{INT:pid:int}\])?: %{GREEDYDATA:message}`,
+}